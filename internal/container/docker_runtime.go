@@ -0,0 +1,256 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	dockererrdefs "github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/jsonmessage"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/controlplaneio/simulator/internal/container/errdefs"
+)
+
+var (
+	ErrImagePull           = errors.New("unable to pull simulator image")
+	ErrImageInspect        = errors.New("unable to inspect simulator image")
+	ErrDigestMismatch      = errors.New("simulator image digest does not match expected digest")
+	ErrPlatformUnsupported = errors.New("requested image platform is incompatible with the docker daemon")
+	ErrImageNotFound       = errors.New("simulator image not found; run `simulator pull`")
+)
+
+// classify turns a raw docker daemon error into one of the errdefs kinds so
+// callers can branch on errdefs.IsNotFound/IsConflict/... instead of
+// string-matching the daemon's message.
+func classify(err error, msg string) error {
+	switch {
+	case dockererrdefs.IsNotFound(err):
+		return errdefs.NewNotFound(fmt.Errorf("%s: %w", msg, err))
+	case dockererrdefs.IsConflict(err):
+		return errdefs.NewConflict(fmt.Errorf("%s: %w", msg, err))
+	case dockererrdefs.IsInvalidParameter(err):
+		return errdefs.NewInvalidParameter(fmt.Errorf("%s: %w", msg, err))
+	default:
+		return errdefs.NewSystem(fmt.Errorf("%s: %w", msg, err))
+	}
+}
+
+// archAliases maps the uname-style architecture names reported by the
+// docker daemon onto the GOARCH values the OCI platform spec expects.
+var archAliases = map[string]string{
+	"x86_64":  "amd64",
+	"amd64":   "amd64",
+	"aarch64": "arm64",
+	"arm64":   "arm64",
+}
+
+// dockerRuntime drives a simulator container via the moby/docker client.
+// It is the default Runtime.
+type dockerRuntime struct {
+	cli *client.Client
+}
+
+func newDockerRuntime() (Runtime, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return nil, errdefs.NewSystem(fmt.Errorf("unable to create docker client: %w", err))
+	}
+
+	return &dockerRuntime{cli: cli}, nil
+}
+
+func (d *dockerRuntime) EnsureImage(ctx context.Context, ref, expectedDigest string) error {
+	if _, _, err := d.cli.ImageInspectWithRaw(ctx, ref); err != nil {
+		if !client.IsErrNotFound(err) {
+			return errdefs.NewSystem(fmt.Errorf("%w: %w", ErrImageInspect, err))
+		}
+
+		if err := d.pullImage(ctx, ref); err != nil {
+			return err
+		}
+	}
+
+	return d.verifyDigest(ctx, ref, expectedDigest)
+}
+
+func (d *dockerRuntime) pullImage(ctx context.Context, ref string) error {
+	reader, err := d.cli.ImagePull(ctx, ref, types.ImagePullOptions{})
+	if err != nil {
+		if dockererrdefs.IsNotFound(err) {
+			return errdefs.NewNotFound(fmt.Errorf("%w: %w", ErrImageNotFound, err))
+		}
+		return errdefs.NewSystem(fmt.Errorf("%w: %w", ErrImagePull, err))
+	}
+	defer reader.Close()
+
+	dec := json.NewDecoder(reader)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errdefs.NewSystem(fmt.Errorf("%w: %w", ErrImagePull, err))
+		}
+
+		if msg.Error != nil {
+			return errdefs.NewSystem(fmt.Errorf("%w: %s", ErrImagePull, msg.Error.Message))
+		}
+
+		_ = msg.Display(os.Stdout)
+	}
+}
+
+// verifyDigest confirms that ref's repo digests include expectedDigest. It
+// is a no-op when no expected digest is configured.
+func (d *dockerRuntime) verifyDigest(ctx context.Context, ref, expectedDigest string) error {
+	if expectedDigest == "" {
+		return nil
+	}
+
+	inspect, _, err := d.cli.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return errdefs.NewSystem(fmt.Errorf("%w: %w", ErrImageInspect, err))
+	}
+
+	for _, digest := range inspect.RepoDigests {
+		if strings.HasSuffix(digest, expectedDigest) {
+			return nil
+		}
+	}
+
+	return errdefs.NewInvalidParameter(fmt.Errorf("%w: %s does not contain digest %s", ErrDigestMismatch, ref, expectedDigest))
+}
+
+// Platform derives the OCI platform of the connected docker daemon so
+// callers don't have to hardcode or guess amd64/arm64.
+func (d *dockerRuntime) Platform(ctx context.Context) (*v1.Platform, error) {
+	info, err := d.cli.Info(ctx)
+	if err != nil {
+		return nil, errdefs.NewSystem(fmt.Errorf("%w: %w", ErrPlatformUnsupported, err))
+	}
+
+	arch, ok := archAliases[info.Architecture]
+	if !ok {
+		return nil, errdefs.NewInvalidParameter(fmt.Errorf("%w: unrecognised daemon architecture %q", ErrPlatformUnsupported, info.Architecture))
+	}
+
+	return &v1.Platform{
+		Architecture: arch,
+		OS:           info.OSType,
+	}, nil
+}
+
+func (d *dockerRuntime) CreateContainer(ctx context.Context, spec ContainerSpec, platform *v1.Platform) (string, error) {
+	if err := validateMounts(spec.Mounts); err != nil {
+		return "", err
+	}
+
+	mounts := make([]mount.Mount, 0, len(spec.Mounts))
+	for _, m := range spec.Mounts {
+		mounts = append(mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   m.Source,
+			Target:   m.Target,
+			ReadOnly: m.ReadOnly,
+		})
+	}
+
+	config := &dockercontainer.Config{
+		Image:        spec.Image,
+		Env:          spec.Env,
+		Cmd:          spec.Cmd,
+		Tty:          spec.TTY,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+	if spec.Interactive {
+		config.AttachStdin = true
+		config.OpenStdin = true
+		config.StdinOnce = true
+	}
+
+	cont, err := d.cli.ContainerCreate(ctx,
+		config,
+		&dockercontainer.HostConfig{Mounts: mounts},
+		&network.NetworkingConfig{},
+		platform,
+		"",
+	)
+	if err != nil {
+		if dockererrdefs.IsNotFound(err) {
+			return "", errdefs.NewNotFound(fmt.Errorf("%w: %w", ErrImageNotFound, err))
+		}
+		return "", classify(err, "unable to create simulator container")
+	}
+
+	return cont.ID, nil
+}
+
+func (d *dockerRuntime) Attach(ctx context.Context, containerID string, interactive, tty bool) (Attachment, error) {
+	hijack, err := d.cli.ContainerAttach(ctx, containerID, types.ContainerAttachOptions{
+		Stream: true,
+		Stdin:  interactive,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return Attachment{}, classify(err, "unable to attach to simulator container")
+	}
+
+	return Attachment{
+		Reader: hijack.Reader,
+		Conn:   netConnWriteCloser{hijack.Conn},
+		TTY:    tty,
+	}, nil
+}
+
+func (d *dockerRuntime) Start(ctx context.Context, containerID string) error {
+	if err := d.cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
+		return classify(err, "unable to start simulator container")
+	}
+
+	return nil
+}
+
+func (d *dockerRuntime) Resize(ctx context.Context, containerID string, height, width uint) error {
+	if err := d.cli.ContainerResize(ctx, containerID, types.ResizeOptions{Height: height, Width: width}); err != nil {
+		return classify(err, "unable to resize simulator container")
+	}
+
+	return nil
+}
+
+func (d *dockerRuntime) Kill(ctx context.Context, containerID, signal string) error {
+	if err := d.cli.ContainerKill(ctx, containerID, signal); err != nil {
+		return classify(err, "unable to signal simulator container")
+	}
+
+	return nil
+}
+
+func (d *dockerRuntime) Stop(ctx context.Context, containerID string) error {
+	if err := d.cli.ContainerStop(ctx, containerID, dockercontainer.StopOptions{}); err != nil {
+		return classify(err, "unable to stop simulator container")
+	}
+
+	return nil
+}
+
+func (d *dockerRuntime) Remove(ctx context.Context, containerID string) error {
+	if err := d.cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{}); err != nil {
+		return classify(err, "unable to remove simulator container")
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+// Package errdefs defines the typed error interfaces that internal/container
+// uses to classify failures coming back from a container engine, modeled on
+// moby/moby's errdefs package. Callers further up the stack (CLI exit codes,
+// human-readable diagnostics) can switch on these interfaces instead of
+// string-matching error messages.
+package errdefs
+
+import "errors"
+
+// NotFound signals that a requested resource (image, container) does not
+// exist.
+type NotFound interface {
+	NotFound() bool
+}
+
+// Conflict signals that the requested operation conflicts with the current
+// state of the resource (e.g. a container name already in use).
+type Conflict interface {
+	Conflict() bool
+}
+
+// InvalidParameter signals that the caller supplied a malformed or
+// unsupported argument.
+type InvalidParameter interface {
+	InvalidParameter() bool
+}
+
+// System signals an unclassified failure in the engine or its transport.
+type System interface {
+	System() bool
+}
+
+// IsNotFound reports whether err, or any error it wraps, is a NotFound.
+func IsNotFound(err error) bool {
+	var e NotFound
+	return errors.As(err, &e) && e.NotFound()
+}
+
+// IsConflict reports whether err, or any error it wraps, is a Conflict.
+func IsConflict(err error) bool {
+	var e Conflict
+	return errors.As(err, &e) && e.Conflict()
+}
+
+// IsInvalidParameter reports whether err, or any error it wraps, is an
+// InvalidParameter.
+func IsInvalidParameter(err error) bool {
+	var e InvalidParameter
+	return errors.As(err, &e) && e.InvalidParameter()
+}
+
+// IsSystem reports whether err, or any error it wraps, is a System error.
+func IsSystem(err error) bool {
+	var e System
+	return errors.As(err, &e) && e.System()
+}
+
+type wrapped struct {
+	error
+	kind string
+}
+
+func (w wrapped) Unwrap() error { return w.error }
+
+func (w wrapped) NotFound() bool         { return w.kind == "not-found" }
+func (w wrapped) Conflict() bool         { return w.kind == "conflict" }
+func (w wrapped) InvalidParameter() bool { return w.kind == "invalid-parameter" }
+func (w wrapped) System() bool           { return w.kind == "system" }
+
+// NewNotFound wraps err so errdefs.IsNotFound(err) reports true while
+// errors.Is/errors.As still see through to err.
+func NewNotFound(err error) error { return wrapped{err, "not-found"} }
+
+// NewConflict wraps err so errdefs.IsConflict(err) reports true.
+func NewConflict(err error) error { return wrapped{err, "conflict"} }
+
+// NewInvalidParameter wraps err so errdefs.IsInvalidParameter(err) reports
+// true.
+func NewInvalidParameter(err error) error { return wrapped{err, "invalid-parameter"} }
+
+// NewSystem wraps err so errdefs.IsSystem(err) reports true. It is the
+// default classification for failures that don't fit a more specific kind.
+func NewSystem(err error) error { return wrapped{err, "system"} }
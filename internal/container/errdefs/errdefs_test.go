@@ -0,0 +1,39 @@
+package errdefs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsNotFound(t *testing.T) {
+	sentinel := errors.New("boom")
+	err := NewNotFound(sentinel)
+
+	if !IsNotFound(err) {
+		t.Fatalf("IsNotFound(%v) = false, want true", err)
+	}
+	if !errors.Is(err, sentinel) {
+		t.Fatalf("errors.Is(%v, sentinel) = false, want true", err)
+	}
+	if IsConflict(err) || IsInvalidParameter(err) || IsSystem(err) {
+		t.Fatalf("%v classified as more than one kind", err)
+	}
+}
+
+func TestNewSystemDoesNotMatchOtherKinds(t *testing.T) {
+	sentinel := errors.New("boom")
+	err := NewSystem(sentinel)
+
+	if !IsSystem(err) {
+		t.Fatalf("IsSystem(%v) = false, want true", err)
+	}
+	if !errors.Is(err, sentinel) {
+		t.Fatalf("errors.Is(%v, sentinel) = false, want true", err)
+	}
+	if IsNotFound(err) {
+		t.Fatalf("IsNotFound(%v) = true, want false", err)
+	}
+	if IsConflict(err) {
+		t.Fatalf("IsConflict(%v) = true, want false", err)
+	}
+}
@@ -0,0 +1,89 @@
+package container
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/moby/term"
+)
+
+// pumpStdin copies os.Stdin into the attachment's connection so an
+// interactive session (a shell, an exec-like command) can receive input.
+// It returns once stdin is closed or the connection is torn down.
+func pumpStdin(attachment Attachment) {
+	_, _ = io.Copy(attachment.Conn, os.Stdin)
+	_ = attachment.Conn.CloseWrite()
+}
+
+// watchResize keeps the container's TTY sized to match the host terminal,
+// resizing once immediately and again on every SIGWINCH.
+func (r simulator) watchResize(ctx context.Context, runtime Runtime, containerID string) {
+	resize := func() {
+		ws, err := term.GetWinsize(os.Stdin.Fd())
+		if err != nil {
+			slog.Warn("failed to read terminal size", "err", err)
+			return
+		}
+
+		if err := runtime.Resize(ctx, containerID, uint(ws.Height), uint(ws.Width)); err != nil {
+			slog.Warn("failed to resize container tty", "id", containerID, "err", err)
+		}
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGWINCH)
+	defer signal.Stop(sig)
+
+	resize()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			resize()
+		}
+	}
+}
+
+// forwardSignals relays SIGINT/SIGTERM received by the CLI process into the
+// container, so Ctrl-C during an interactive session stops the process it
+// is attached to rather than just the CLI. It stops watching once ctx is
+// done, so the signal handler doesn't outlive the Run call that started it.
+func (r simulator) forwardSignals(ctx context.Context, runtime Runtime, containerID string) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s := <-sig:
+			name := signalName(s)
+			slog.Info("forwarding signal to container", "signal", name, "id", containerID)
+
+			killCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err := runtime.Kill(killCtx, containerID, name)
+			cancel()
+			if err != nil {
+				slog.Warn("failed to forward signal to container", "id", containerID, "err", err)
+			}
+		}
+	}
+}
+
+func signalName(s os.Signal) string {
+	switch s {
+	case syscall.SIGINT:
+		return "SIGINT"
+	case syscall.SIGTERM:
+		return "SIGTERM"
+	default:
+		return s.String()
+	}
+}
@@ -0,0 +1,52 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ErrDuplicateMountTarget = errors.New("duplicate mount target")
+	ErrInvalidBindMount     = errors.New("invalid bind mount")
+)
+
+// reservedMountTargets collide with paths the container runtime or kernel
+// manages itself and must never be overridden by a bind mount.
+var reservedMountTargets = map[string]bool{
+	"/proc": true,
+	"/sys":  true,
+	"/dev":  true,
+}
+
+// validateMounts checks mounts for duplicate targets, bind sources that
+// don't exist or aren't absolute, and targets that collide with reserved
+// kernel mount points. It is called before a Runtime creates the container
+// so a misconfigured mount (e.g. a user's config accidentally re-binding
+// controlplane.HomeDir) fails with an actionable error instead of being
+// silently accepted by the engine.
+func validateMounts(mounts []Mount) error {
+	targets := make(map[string]bool, len(mounts))
+
+	for _, m := range mounts {
+		if targets[m.Target] {
+			return fmt.Errorf("%w: %s", ErrDuplicateMountTarget, m.Target)
+		}
+		targets[m.Target] = true
+
+		if reservedMountTargets[m.Target] {
+			return fmt.Errorf("%w: %s is reserved and cannot be a mount target", ErrInvalidBindMount, m.Target)
+		}
+
+		if !filepath.IsAbs(m.Source) {
+			return fmt.Errorf("%w: source %s is not an absolute path", ErrInvalidBindMount, m.Source)
+		}
+
+		if _, err := os.Stat(m.Source); err != nil {
+			return fmt.Errorf("%w: source %s: %w", ErrInvalidBindMount, m.Source, err)
+		}
+	}
+
+	return nil
+}
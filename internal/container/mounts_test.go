@@ -0,0 +1,70 @@
+package container
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateMounts(t *testing.T) {
+	tmp := t.TempDir()
+
+	tests := []struct {
+		name    string
+		mounts  []Mount
+		wantErr error
+	}{
+		{
+			name: "valid mounts",
+			mounts: []Mount{
+				{Source: tmp, Target: "/home/ubuntu/.controlplane"},
+				{Source: tmp, Target: "/home/ubuntu/.aws", ReadOnly: true},
+			},
+		},
+		{
+			name: "duplicate target",
+			mounts: []Mount{
+				{Source: tmp, Target: "/home/ubuntu/.aws"},
+				{Source: tmp, Target: "/home/ubuntu/.aws"},
+			},
+			wantErr: ErrDuplicateMountTarget,
+		},
+		{
+			name: "reserved target",
+			mounts: []Mount{
+				{Source: tmp, Target: "/proc"},
+			},
+			wantErr: ErrInvalidBindMount,
+		},
+		{
+			name: "relative source",
+			mounts: []Mount{
+				{Source: "relative/path", Target: "/home/ubuntu/.aws"},
+			},
+			wantErr: ErrInvalidBindMount,
+		},
+		{
+			name: "missing source",
+			mounts: []Mount{
+				{Source: tmp + "/does-not-exist", Target: "/home/ubuntu/.aws"},
+			},
+			wantErr: ErrInvalidBindMount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMounts(tt.mounts)
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("validateMounts() = %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("validateMounts() = %v, want error wrapping %v", err, tt.wantErr)
+			}
+		})
+	}
+}
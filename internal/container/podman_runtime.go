@@ -0,0 +1,396 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/controlplaneio/simulator/internal/container/errdefs"
+)
+
+const podmanAPIVersion = "v4.0.0"
+
+var ErrPodmanSocket = errors.New("unable to reach podman socket")
+
+// classifyStatus turns a libpod REST status code into one of the errdefs
+// kinds, mirroring classify() for the docker runtime.
+func classifyStatus(status int, msg string) error {
+	err := fmt.Errorf("%s: podman returned status %d", msg, status)
+
+	switch status {
+	case http.StatusNotFound:
+		return errdefs.NewNotFound(err)
+	case http.StatusConflict:
+		return errdefs.NewConflict(err)
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return errdefs.NewInvalidParameter(err)
+	default:
+		return errdefs.NewSystem(err)
+	}
+}
+
+// podmanRuntime drives a simulator container via the Podman libpod REST
+// API, reached over the rootless user socket. It lets Fedora/RHEL users
+// run the simulator without installing Docker Desktop.
+type podmanRuntime struct {
+	sock string
+	http *http.Client
+}
+
+func newPodmanRuntime() (Runtime, error) {
+	sock := os.Getenv("PODMAN_SOCK")
+	if sock == "" {
+		sock = podmanSocketPath()
+	}
+	if sock == "" {
+		return nil, errdefs.NewSystem(fmt.Errorf("%w: no rootless podman socket found (set XDG_RUNTIME_DIR or PODMAN_SOCK)", ErrPodmanSocket))
+	}
+
+	return &podmanRuntime{
+		sock: sock,
+		http: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", sock)
+				},
+			},
+		},
+	}, nil
+}
+
+func (p *podmanRuntime) url(format string, a ...any) string {
+	return "http://d/" + podmanAPIVersion + "/libpod" + fmt.Sprintf(format, a...)
+}
+
+func (p *podmanRuntime) do(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, reader)
+	if err != nil {
+		return nil, errdefs.NewSystem(fmt.Errorf("%w: %w", ErrPodmanSocket, err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, errdefs.NewSystem(fmt.Errorf("%w: %w", ErrPodmanSocket, err))
+	}
+
+	return resp, nil
+}
+
+func (p *podmanRuntime) EnsureImage(ctx context.Context, ref, expectedDigest string) error {
+	resp, err := p.do(ctx, http.MethodGet, p.url("/images/%s/json", url.PathEscape(ref)), nil)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return classifyStatus(resp.StatusCode, ErrImageInspect.Error())
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		if err := p.pullImage(ctx, ref); err != nil {
+			return err
+		}
+	}
+
+	if expectedDigest == "" {
+		return nil
+	}
+
+	inspect, err := p.do(ctx, http.MethodGet, p.url("/images/%s/json", url.PathEscape(ref)), nil)
+	if err != nil {
+		return err
+	}
+	defer inspect.Body.Close()
+
+	var info struct {
+		Digest string `json:"Digest"`
+	}
+	if err := json.NewDecoder(inspect.Body).Decode(&info); err != nil {
+		return errdefs.NewSystem(fmt.Errorf("%w: %w", ErrImageInspect, err))
+	}
+
+	if info.Digest != expectedDigest {
+		return errdefs.NewInvalidParameter(fmt.Errorf("%w: %s resolved to %s, expected %s", ErrDigestMismatch, ref, info.Digest, expectedDigest))
+	}
+
+	return nil
+}
+
+// pullImage pulls ref via the libpod images/pull endpoint, decoding its
+// newline-delimited JSON progress stream and echoing it to stdout the same
+// way dockerRuntime.pullImage does for the docker backend.
+func (p *podmanRuntime) pullImage(ctx context.Context, ref string) error {
+	pull, err := p.do(ctx, http.MethodPost, p.url("/images/pull?reference=%s", url.QueryEscape(ref)), nil)
+	if err != nil {
+		return errdefs.NewSystem(fmt.Errorf("%w: %w", ErrImagePull, err))
+	}
+	defer pull.Body.Close()
+
+	if pull.StatusCode == http.StatusNotFound {
+		return errdefs.NewNotFound(fmt.Errorf("%w: %w", ErrImageNotFound, classifyStatus(pull.StatusCode, "pull failed")))
+	}
+	if pull.StatusCode != http.StatusOK {
+		return classifyStatus(pull.StatusCode, ErrImagePull.Error())
+	}
+
+	dec := json.NewDecoder(pull.Body)
+	for {
+		var msg struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+			ID     string `json:"id"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errdefs.NewSystem(fmt.Errorf("%w: %w", ErrImagePull, err))
+		}
+
+		if msg.Error != "" {
+			return errdefs.NewSystem(fmt.Errorf("%w: %s", ErrImagePull, msg.Error))
+		}
+
+		switch {
+		case msg.Stream != "":
+			fmt.Fprint(os.Stdout, msg.Stream)
+		case msg.ID != "":
+			fmt.Fprintln(os.Stdout, msg.ID)
+		}
+	}
+}
+
+func (p *podmanRuntime) Platform(ctx context.Context) (*v1.Platform, error) {
+	resp, err := p.do(ctx, http.MethodGet, p.url("/info"), nil)
+	if err != nil {
+		return nil, errdefs.NewSystem(fmt.Errorf("%w: %w", ErrPlatformUnsupported, err))
+	}
+	defer resp.Body.Close()
+
+	var info struct {
+		Host struct {
+			Arch string `json:"arch"`
+			OS   string `json:"os"`
+		} `json:"host"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, errdefs.NewSystem(fmt.Errorf("%w: %w", ErrPlatformUnsupported, err))
+	}
+
+	arch, ok := archAliases[info.Host.Arch]
+	if !ok {
+		return nil, errdefs.NewInvalidParameter(fmt.Errorf("%w: unrecognised daemon architecture %q", ErrPlatformUnsupported, info.Host.Arch))
+	}
+
+	return &v1.Platform{Architecture: arch, OS: info.Host.OS}, nil
+}
+
+func (p *podmanRuntime) CreateContainer(ctx context.Context, spec ContainerSpec, platform *v1.Platform) (string, error) {
+	if err := validateMounts(spec.Mounts); err != nil {
+		return "", err
+	}
+
+	mounts := make([]map[string]any, 0, len(spec.Mounts))
+	for _, m := range spec.Mounts {
+		mounts = append(mounts, map[string]any{
+			"type":        "bind",
+			"source":      m.Source,
+			"destination": m.Target,
+			"options":     readOnlyOption(m.ReadOnly),
+		})
+	}
+
+	create := map[string]any{
+		"image":       spec.Image,
+		"command":     spec.Cmd,
+		"env":         envMap(spec.Env),
+		"terminal":    spec.TTY,
+		"stdin":       spec.Interactive,
+		"mounts":      mounts,
+		"oci_runtime": "",
+	}
+	if platform != nil {
+		create["os"] = platform.OS
+		create["arch"] = platform.Architecture
+	}
+
+	resp, err := p.do(ctx, http.MethodPost, p.url("/containers/create"), create)
+	if err != nil {
+		return "", errdefs.NewSystem(fmt.Errorf("unable to create simulator container: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errdefs.NewNotFound(fmt.Errorf("%w: %w", ErrImageNotFound, classifyStatus(resp.StatusCode, "create failed")))
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return "", classifyStatus(resp.StatusCode, "unable to create simulator container")
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", errdefs.NewSystem(fmt.Errorf("unable to create simulator container: %w", err))
+	}
+
+	return created.ID, nil
+}
+
+// Attach dials the podman socket directly and issues the attach request by
+// hand, since a successful attach upgrades the connection to a raw stdio
+// stream rather than returning a normal HTTP response.
+func (p *podmanRuntime) Attach(ctx context.Context, containerID string, interactive, tty bool) (Attachment, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", p.sock)
+	if err != nil {
+		return Attachment{}, errdefs.NewSystem(fmt.Errorf("unable to attach to simulator container: %w", err))
+	}
+
+	path := p.url("/containers/%s/attach?stream=true&stdout=true&stderr=true", containerID)
+	if interactive {
+		path += "&stdin=true"
+	}
+
+	req, err := http.NewRequest(http.MethodPost, path, nil)
+	if err != nil {
+		_ = conn.Close()
+		return Attachment{}, errdefs.NewSystem(fmt.Errorf("unable to attach to simulator container: %w", err))
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return Attachment{}, errdefs.NewSystem(fmt.Errorf("unable to attach to simulator container: %w", err))
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		_ = conn.Close()
+		return Attachment{}, errdefs.NewSystem(fmt.Errorf("unable to attach to simulator container: %w", err))
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols && resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return Attachment{}, classifyStatus(resp.StatusCode, "unable to attach to simulator container")
+	}
+
+	return Attachment{Reader: reader, Conn: netConnWriteCloser{conn}, TTY: tty}, nil
+}
+
+func (p *podmanRuntime) Start(ctx context.Context, containerID string) error {
+	resp, err := p.do(ctx, http.MethodPost, p.url("/containers/%s/start", containerID), nil)
+	if err != nil {
+		return errdefs.NewSystem(fmt.Errorf("unable to start simulator container: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return classifyStatus(resp.StatusCode, "unable to start simulator container")
+	}
+
+	return nil
+}
+
+func (p *podmanRuntime) Resize(ctx context.Context, containerID string, height, width uint) error {
+	resp, err := p.do(ctx, http.MethodPost, p.url("/containers/%s/resize?h=%s&w=%s", containerID, strconv.FormatUint(uint64(height), 10), strconv.FormatUint(uint64(width), 10)), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return classifyStatus(resp.StatusCode, "unable to resize simulator container")
+	}
+
+	return nil
+}
+
+func (p *podmanRuntime) Kill(ctx context.Context, containerID, signal string) error {
+	resp, err := p.do(ctx, http.MethodPost, p.url("/containers/%s/kill?signal=%s", containerID, signal), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return classifyStatus(resp.StatusCode, "unable to signal simulator container")
+	}
+
+	return nil
+}
+
+func (p *podmanRuntime) Stop(ctx context.Context, containerID string) error {
+	resp, err := p.do(ctx, http.MethodPost, p.url("/containers/%s/stop", containerID), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return classifyStatus(resp.StatusCode, "unable to stop simulator container")
+	}
+
+	return nil
+}
+
+func (p *podmanRuntime) Remove(ctx context.Context, containerID string) error {
+	resp, err := p.do(ctx, http.MethodDelete, p.url("/containers/%s", containerID), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return classifyStatus(resp.StatusCode, "unable to remove simulator container")
+	}
+
+	return nil
+}
+
+func readOnlyOption(ro bool) []string {
+	if ro {
+		return []string{"ro"}
+	}
+	return []string{"rw"}
+}
+
+func envMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, kv := range env {
+		for i := 0; i < len(kv); i++ {
+			if kv[i] == '=' {
+				m[kv[:i]] = kv[i+1:]
+				break
+			}
+		}
+	}
+	return m
+}
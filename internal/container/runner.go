@@ -2,7 +2,7 @@ package container
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
@@ -10,27 +10,16 @@ import (
 	"sync"
 	"time"
 
-	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/mount"
-	"github.com/docker/docker/api/types/network"
-	"github.com/docker/docker/client"
-	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/moby/term"
 
 	"github.com/controlplaneio/simulator/controlplane"
 	"github.com/controlplaneio/simulator/controlplane/aws"
 	"github.com/controlplaneio/simulator/internal/config"
+	"github.com/controlplaneio/simulator/internal/container/errdefs"
 )
 
-var (
-	NoHome       = errors.New("unable to determine your home directory")
-	NoClient     = errors.New("unable to create docker client")
-	CreateFailed = errors.New("unable to create simulator container")
-	StartFailed  = errors.New("unable to start simulator container")
-	AttachFailed = errors.New("unable to attach to simulator container")
-
-	containerAwsDir = "/home/ubuntu/.aws"
-)
+var containerAwsDir = "/home/ubuntu/.aws"
 
 type Simulator interface {
 	Run(ctx context.Context, command []string) error
@@ -49,42 +38,46 @@ type simulator struct {
 func (r simulator) Run(ctx context.Context, command []string) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return NoHome
+		return errdefs.NewSystem(fmt.Errorf("unable to determine your home directory: %w", err))
+	}
+
+	runtime, err := newRuntime(r.Config)
+	if err != nil {
+		return err
+	}
+
+	if err := runtime.EnsureImage(ctx, r.Config.Container.Image, r.Config.Container.ExpectedDigest); err != nil {
+		return err
 	}
 
-	cli, err := client.NewClientWithOpts(client.FromEnv)
+	platform, err := runtime.Platform(ctx)
 	if err != nil {
-		return NoClient
+		return err
 	}
 
-	mounts := []mount.Mount{
+	mounts := []Mount{
 		{
-			Type:     mount.TypeBind,
 			Source:   filepath.Join(r.Config.BaseDir, controlplane.Home),
 			Target:   controlplane.HomeDir,
 			ReadOnly: false,
 		},
 		{
-			Type:   mount.TypeBind,
 			Source: filepath.Join(home, ".aws"),
 			Target: containerAwsDir,
 		},
 	}
 
 	if r.Config.Cli.Dev {
-		mounts = append(mounts, []mount.Mount{
+		mounts = append(mounts, []Mount{
 			{
-				Type:   mount.TypeBind,
 				Source: filepath.Join(r.Config.BaseDir, controlplane.Scenarios),
 				Target: controlplane.AnsibleDir,
 			},
 			{
-				Type:   mount.TypeBind,
 				Source: filepath.Join(r.Config.BaseDir, controlplane.Packer),
 				Target: controlplane.PackerTemplateDir,
 			},
 			{
-				Type:     mount.TypeBind,
 				Source:   filepath.Join(r.Config.BaseDir, controlplane.Terraform),
 				Target:   controlplane.TerraformDir,
 				ReadOnly: false,
@@ -92,61 +85,62 @@ func (r simulator) Run(ctx context.Context, command []string) error {
 		}...)
 	}
 
-	cont, err := cli.ContainerCreate(ctx,
-		&container.Config{
-			Image:        r.Config.Container.Image,
-			Env:          aws.Env,
-			Cmd:          command,
-			Tty:          true,
-			AttachStdout: true,
-			AttachStderr: true,
-		},
-		&container.HostConfig{
-			Mounts: mounts,
-		},
-		&network.NetworkingConfig{},
-		&v1.Platform{},
-		"",
-	)
+	interactive := r.Config.Cli.Interactive
+	tty := term.IsTerminal(os.Stdout.Fd())
+
+	containerID, err := runtime.CreateContainer(ctx, ContainerSpec{
+		Image:       r.Config.Container.Image,
+		Env:         aws.Env,
+		Cmd:         command,
+		Mounts:      mounts,
+		Interactive: interactive,
+		TTY:         tty,
+	}, platform)
 	if err != nil {
-		return CreateFailed
+		return err
 	}
 	defer func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
 
-		err = cli.ContainerStop(ctx, cont.ID, container.StopOptions{})
-		if err != nil {
-			slog.Warn("failed to stop container", "id", cont.ID, "err", err)
+		if err := runtime.Stop(ctx, containerID); err != nil {
+			slog.Warn("failed to stop container", "id", containerID, "err", err)
 		}
 
-		err = cli.ContainerRemove(ctx, cont.ID, types.ContainerRemoveOptions{})
-		if err != nil {
-			slog.Warn("failed to remove container", "id", cont.ID, "err", err)
+		if err := runtime.Remove(ctx, containerID); err != nil {
+			slog.Warn("failed to remove container", "id", containerID, "err", err)
 		}
 	}()
 
-	hijack, err := cli.ContainerAttach(ctx, cont.ID, types.ContainerAttachOptions{
-		Stream: true,
-		Stdout: true,
-		Stderr: true,
-	})
+	attachment, err := runtime.Attach(ctx, containerID, interactive, tty)
 	if err != nil {
-		return AttachFailed
+		return err
 	}
 
-	err = cli.ContainerStart(ctx, cont.ID, types.ContainerStartOptions{})
-	if err != nil {
-		return StartFailed
+	if err := runtime.Start(ctx, containerID); err != nil {
+		return err
 	}
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
-		_, _ = io.Copy(os.Stdout, hijack.Reader)
 		defer wg.Done()
+		if attachment.TTY {
+			_, _ = io.Copy(os.Stdout, attachment.Reader)
+		} else {
+			_, _ = stdcopy.StdCopy(os.Stdout, os.Stderr, attachment.Reader)
+		}
 	}()
 
+	if interactive {
+		sessionCtx, cancelSession := context.WithCancel(ctx)
+		defer cancelSession()
+
+		go pumpStdin(attachment)
+		go r.watchResize(sessionCtx, runtime, containerID)
+		go r.forwardSignals(sessionCtx, runtime, containerID)
+	}
+
 	wg.Wait()
 
 	return nil
@@ -0,0 +1,125 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/controlplaneio/simulator/internal/config"
+	"github.com/controlplaneio/simulator/internal/container/errdefs"
+)
+
+var ErrUnknownRuntime = errors.New("unknown container runtime")
+
+// ContainerSpec is the runtime-agnostic description of the container a
+// Simulator wants to run, independent of any one engine's SDK types.
+type ContainerSpec struct {
+	Image       string
+	Env         []string
+	Cmd         []string
+	Mounts      []Mount
+	Interactive bool
+	TTY         bool
+}
+
+// Mount is a single bind mount, shared across Runtime implementations.
+type Mount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// WriteCloser is the write half of an attached container connection. It
+// supports CloseWrite so pumpStdin can signal EOF to the container (e.g.
+// Ctrl-D in an interactive shell) without tearing down the read side, which
+// a plain Close on a hijacked socket would do.
+type WriteCloser interface {
+	io.Writer
+	CloseWrite() error
+}
+
+// Attachment is a live stream to a running container's stdio, returned by
+// Runtime.Attach.
+type Attachment struct {
+	Reader io.Reader
+	Conn   WriteCloser
+	TTY    bool
+}
+
+// netConnWriteCloser adapts a net.Conn into a WriteCloser that half-closes
+// via the connection's own CloseWrite when supported (TCP and unix socket
+// conns both implement it), falling back to a full Close otherwise.
+type netConnWriteCloser struct {
+	net.Conn
+}
+
+func (c netConnWriteCloser) CloseWrite() error {
+	if cw, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+
+	return c.Conn.Close()
+}
+
+// Runtime abstracts the container engine a Simulator drives (Docker,
+// Podman, ...) so the rest of the package isn't coupled to any one
+// engine's SDK.
+type Runtime interface {
+	EnsureImage(ctx context.Context, ref, expectedDigest string) error
+	Platform(ctx context.Context) (*v1.Platform, error)
+	CreateContainer(ctx context.Context, spec ContainerSpec, platform *v1.Platform) (string, error)
+	Attach(ctx context.Context, containerID string, interactive, tty bool) (Attachment, error)
+	Start(ctx context.Context, containerID string) error
+	Resize(ctx context.Context, containerID string, height, width uint) error
+	Kill(ctx context.Context, containerID, signal string) error
+	Stop(ctx context.Context, containerID string) error
+	Remove(ctx context.Context, containerID string) error
+}
+
+// newRuntime resolves the Runtime implementation selected by
+// cfg.Container.Runtime ("docker", "podman" or "auto", which probes
+// DOCKER_HOST and the rootless podman socket).
+func newRuntime(cfg *config.Config) (Runtime, error) {
+	mode := cfg.Container.Runtime
+	if mode == "" || mode == "auto" {
+		mode = probeRuntimeMode()
+	}
+
+	switch mode {
+	case "docker":
+		return newDockerRuntime()
+	case "podman":
+		return newPodmanRuntime()
+	default:
+		return nil, errdefs.NewInvalidParameter(fmt.Errorf("%w: %q", ErrUnknownRuntime, cfg.Container.Runtime))
+	}
+}
+
+func probeRuntimeMode() string {
+	if os.Getenv("DOCKER_HOST") != "" {
+		return "docker"
+	}
+
+	if sock := podmanSocketPath(); sock != "" {
+		if _, err := os.Stat(sock); err == nil {
+			return "podman"
+		}
+	}
+
+	return "docker"
+}
+
+func podmanSocketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return ""
+	}
+
+	return filepath.Join(dir, "podman", "podman.sock")
+}